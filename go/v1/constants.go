@@ -8,16 +8,24 @@ const (
 	Magic0 uint8 = 0x52
 	Magic1 uint8 = 0x54
 
-	FrameAuth       uint8 = 0x01
-	FrameOpenStream uint8 = 0x02
-	FrameData       uint8 = 0x03
-	FrameClose      uint8 = 0x04
-	FrameHeartbeat  uint8 = 0x05
+	FrameAuth         uint8 = 0x01
+	FrameOpenStream   uint8 = 0x02
+	FrameData         uint8 = 0x03
+	FrameClose        uint8 = 0x04
+	FrameHeartbeat    uint8 = 0x05
+	FrameSettings     uint8 = 0x06
+	FrameWindowUpdate uint8 = 0x07
+	FrameGoAway       uint8 = 0x08
+	FrameRstStream    uint8 = 0x09
 
 	// HeaderSize: Magic(2) + Version(1) + Type(1) + Flags(1) + StreamID(4) = 9 bytes
 	HeaderSize   = 9
 	MaxFrameSize = 16 * 1024 * 1024 // 16MB
 
+	// Flow control defaults (see FlowController):
+	DefaultInitialWindowSize = 65535         // 64KB - 1, same default HTTP/2 uses
+	MaxWindowSize            = (1 << 31) - 1 // ceiling a flow-control window is clamped to
+
 	// StreamID quy ước:
 	// StreamID == 0 → control frame (Auth, Heartbeat, Error global)
 	// StreamID > 0  → data stream (OpenStream, Data, Close)
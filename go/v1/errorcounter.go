@@ -0,0 +1,33 @@
+package v1
+
+// ErrorCounter observes individual decode failures by a stable short token,
+// so operators can see which specific check is firing (bad magic vs. bad
+// version vs. truncated payload vs. unknown type) instead of every parse
+// failure collapsing into one opaque *ProtocolError. Modeled on the HTTP/2
+// framer's CountError(errToken string) hook.
+//
+// The token set is stable API - treat it as a fixed enum so Prometheus label
+// cardinality stays bounded:
+//
+//	frame_bad_magic        - magic marker mismatch
+//	frame_bad_version      - version byte mismatch
+//	frame_size_too_small   - length below HeaderSize
+//	frame_size_too_large   - length above the frame size limit
+//	frame_bad_type         - unrecognized frame type
+//	frame_short_read       - truncated read of the length prefix or body
+type ErrorCounter func(token string)
+
+// DefaultErrorCounter is invoked by the package-level Decode/DecodeWithLimit
+// when no Framer-scoped ErrorCounter applies. Nil (the default) disables
+// counting.
+var DefaultErrorCounter ErrorCounter
+
+func countDecodeError(counter ErrorCounter, token string) {
+	if counter != nil {
+		counter(token)
+		return
+	}
+	if DefaultErrorCounter != nil {
+		DefaultErrorCounter(token)
+	}
+}
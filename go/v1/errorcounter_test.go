@@ -0,0 +1,119 @@
+package v1
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+func TestDecodeErrorCounterTokens(t *testing.T) {
+	validFrame := &Frame{
+		Version:  Version,
+		Type:     FrameAuth,
+		Flags:    FlagNone,
+		StreamID: StreamIDControl,
+		Payload:  []byte("test"),
+	}
+	var validBuf bytes.Buffer
+	if err := Encode(&validBuf, validFrame); err != nil {
+		t.Fatalf("Encode failed: %v", err)
+	}
+	validBytes := validBuf.Bytes()
+
+	tests := []struct {
+		name      string
+		input     []byte
+		wantToken string
+	}{
+		{
+			name:      "short read on length prefix",
+			input:     []byte{0x00, 0x00},
+			wantToken: "frame_short_read",
+		},
+		{
+			name:      "length too small",
+			input:     lengthPrefixed(HeaderSize - 1),
+			wantToken: "frame_size_too_small",
+		},
+		{
+			name:      "length too large",
+			input:     lengthPrefixed(MaxFrameSize + 1),
+			wantToken: "frame_size_too_large",
+		},
+		{
+			name:      "short read on body",
+			input:     validBytes[:len(validBytes)-2],
+			wantToken: "frame_short_read",
+		},
+		{
+			name:      "bad magic",
+			input:     corrupt(validBytes, 4, 0xFF),
+			wantToken: "frame_bad_magic",
+		},
+		{
+			name:      "bad version",
+			input:     corrupt(validBytes, 6, 0xFF),
+			wantToken: "frame_bad_version",
+		},
+		{
+			name:      "bad type",
+			input:     corrupt(validBytes, 7, 0xFF),
+			wantToken: "frame_bad_type",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			counter := ErrorCounter(func(token string) { got = token })
+
+			_, err := decodeFrame(bytes.NewReader(tt.input), MaxFrameSize, counter, freshBuffer)
+			if err == nil {
+				t.Fatal("expected decode to fail")
+			}
+			if got != tt.wantToken {
+				t.Errorf("token = %q, want %q", got, tt.wantToken)
+			}
+		})
+	}
+}
+
+func TestFramerErrorCounterTokens(t *testing.T) {
+	var got string
+	buf := bytes.NewBuffer(lengthPrefixed(HeaderSize - 1))
+	fr := NewFramer(buf)
+	fr.SetErrorCounter(func(token string) { got = token })
+
+	if _, err := fr.ReadFrame(); err == nil {
+		t.Fatal("expected ReadFrame to fail")
+	}
+	if got != "frame_size_too_small" {
+		t.Errorf("token = %q, want frame_size_too_small", got)
+	}
+}
+
+func TestDefaultErrorCounterFallback(t *testing.T) {
+	var got string
+	DefaultErrorCounter = func(token string) { got = token }
+	defer func() { DefaultErrorCounter = nil }()
+
+	_, err := Decode(bytes.NewReader(lengthPrefixed(HeaderSize - 1)))
+	if err == nil {
+		t.Fatal("expected Decode to fail")
+	}
+	if got != "frame_size_too_small" {
+		t.Errorf("token = %q, want frame_size_too_small", got)
+	}
+}
+
+func lengthPrefixed(length uint32) []byte {
+	buf := make([]byte, 4)
+	binary.BigEndian.PutUint32(buf, length)
+	return buf
+}
+
+func corrupt(data []byte, offset int, value byte) []byte {
+	out := append([]byte(nil), data...)
+	out[offset] = value
+	return out
+}
@@ -19,6 +19,7 @@ const (
 	ErrCodeFrameTooLarge  ErrorCode = 1002
 	ErrCodeBadFrame       ErrorCode = 1003
 	ErrCodeBadPayload     ErrorCode = 1004
+	ErrCodeFlowControl    ErrorCode = 1005
 
 	// ====== Auth / Handshake ======
 	ErrCodeUnauthorized ErrorCode = 2001
@@ -27,6 +28,7 @@ const (
 	// ====== Stream ======
 	ErrCodeStreamNotFound ErrorCode = 3001
 	ErrCodeStreamClosed  ErrorCode = 3002
+	ErrCodeStreamRefused ErrorCode = 3003
 )
 
 /*
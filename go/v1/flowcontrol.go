@@ -0,0 +1,287 @@
+package v1
+
+import (
+	"context"
+	"encoding/binary"
+	"sync"
+)
+
+// EncodeWindowUpdate builds a WINDOW_UPDATE frame granting increment bytes
+// of additional send credit. streamID == StreamIDControl updates the
+// connection-level window; streamID > 0 updates that stream's window.
+func EncodeWindowUpdate(streamID uint32, increment uint32) (*Frame, error) {
+	if increment == 0 {
+		return nil, NewError(ErrCodeBadPayload, "window update increment must be > 0")
+	}
+
+	payload := make([]byte, 4)
+	binary.BigEndian.PutUint32(payload, increment)
+
+	return &Frame{
+		Version:  Version,
+		Type:     FrameWindowUpdate,
+		Flags:    FlagNone,
+		StreamID: streamID,
+		Payload:  payload,
+	}, nil
+}
+
+// DecodeWindowUpdate parses a WINDOW_UPDATE payload, returning the window
+// size increment. A zero increment is rejected with ErrCodeBadPayload.
+func DecodeWindowUpdate(payload []byte) (uint32, error) {
+	if len(payload) != 4 {
+		return 0, NewError(ErrCodeBadPayload, "window update payload must be 4 bytes")
+	}
+
+	increment := binary.BigEndian.Uint32(payload)
+	if increment == 0 {
+		return 0, NewError(ErrCodeBadPayload, "window update increment must be > 0")
+	}
+
+	return increment, nil
+}
+
+// window is a single flow-controlled credit counter (either the connection
+// window or one stream's window). notify is closed and replaced every time
+// the window is replenished, so WaitForCredit can block on it without
+// polling.
+type window struct {
+	mu     sync.Mutex
+	size   int64
+	notify chan struct{}
+}
+
+func newWindow(initial uint32) *window {
+	return &window{size: int64(initial), notify: make(chan struct{})}
+}
+
+func (w *window) snapshot() (int64, chan struct{}) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.size, w.notify
+}
+
+func (w *window) consume(n int64) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if n > w.size {
+		return NewError(ErrCodeFlowControl, "insufficient window credit")
+	}
+	w.size -= n
+	return nil
+}
+
+// replenish adds n credit to the window, clamped to MaxWindowSize so a
+// misbehaving peer sending oversized WINDOW_UPDATEs can't overflow the
+// counter past the protocol's 2^31-1 ceiling.
+func (w *window) replenish(n uint32) {
+	w.mu.Lock()
+	w.size += int64(n)
+	if w.size > MaxWindowSize {
+		w.size = MaxWindowSize
+	}
+	old := w.notify
+	w.notify = make(chan struct{})
+	w.mu.Unlock()
+	close(old)
+}
+
+// consumeRecv deducts n bytes of inbound DATA from a receive-side window -
+// i.e. credit we previously granted the peer - failing with ErrCodeFlowControl
+// if the peer sent more than it was granted. Once remaining credit drops
+// below half of initial, the window is topped back up to initial and
+// consumeRecv reports the regranted increment so the caller can tell the peer
+// about it via WINDOW_UPDATE.
+func (w *window) consumeRecv(n int64, initial uint32) (increment uint32, ok bool, err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if n > w.size {
+		return 0, false, NewError(ErrCodeFlowControl, "peer exceeded granted receive window")
+	}
+	w.size -= n
+	if w.size >= int64(initial)/2 {
+		return 0, false, nil
+	}
+	increment = initial - uint32(w.size)
+	w.size = int64(initial)
+	return increment, true, nil
+}
+
+// SplitForWindow splits payload into chunks of at most window bytes each, so
+// a FrameData send can be gated by min(streamSendWindow, connSendWindow)
+// without ever asking Encode to write more than the receiver has credit for.
+// window <= 0 returns the payload as a single chunk (no splitting).
+func SplitForWindow(payload []byte, window int) [][]byte {
+	if window <= 0 || len(payload) <= window {
+		return [][]byte{payload}
+	}
+
+	chunks := make([][]byte, 0, (len(payload)+window-1)/window)
+	for len(payload) > 0 {
+		n := window
+		if n > len(payload) {
+			n = len(payload)
+		}
+		chunks = append(chunks, payload[:n])
+		payload = payload[n:]
+	}
+	return chunks
+}
+
+// FlowController tracks send/receive window credit per stream plus one
+// connection-level window shared by all streams, per the SETTINGS/WINDOW_UPDATE
+// scheme: a FrameData send is gated by min(streamWindow, connWindow).
+type FlowController struct {
+	mu            sync.Mutex
+	conn          *window
+	streams       map[uint32]*window
+	recvConn      *window
+	recvStreams   map[uint32]*window
+	initialWindow uint32
+}
+
+// NewFlowController returns a FlowController whose connection window and new
+// stream windows start at initialWindowSize (typically DefaultInitialWindowSize
+// or a negotiated SettingInitialWindowSize). This also grants initialWindowSize
+// of receive-side credit to the peer; see OnDataReceived.
+func NewFlowController(initialWindowSize uint32) *FlowController {
+	return &FlowController{
+		conn:          newWindow(initialWindowSize),
+		streams:       make(map[uint32]*window),
+		recvConn:      newWindow(initialWindowSize),
+		recvStreams:   make(map[uint32]*window),
+		initialWindow: initialWindowSize,
+	}
+}
+
+func (fc *FlowController) streamWindow(streamID uint32) *window {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	w, ok := fc.streams[streamID]
+	if !ok {
+		w = newWindow(fc.initialWindow)
+		fc.streams[streamID] = w
+	}
+	return w
+}
+
+func (fc *FlowController) recvStreamWindow(streamID uint32) *window {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	w, ok := fc.recvStreams[streamID]
+	if !ok {
+		w = newWindow(fc.initialWindow)
+		fc.recvStreams[streamID] = w
+	}
+	return w
+}
+
+// Consume deducts n bytes from both the stream's window and the connection
+// window, failing with ErrCodeFlowControl if either lacks sufficient credit.
+// A stream-level deduction is rolled back if the connection window rejects it,
+// so a failed Consume never leaves the stream window short.
+func (fc *FlowController) Consume(streamID uint32, n int) error {
+	if streamID == StreamIDControl {
+		return NewError(ErrCodeFlowControl, "control stream is not flow controlled")
+	}
+
+	sw := fc.streamWindow(streamID)
+	if err := sw.consume(int64(n)); err != nil {
+		return err
+	}
+	if err := fc.conn.consume(int64(n)); err != nil {
+		sw.replenish(uint32(n))
+		return err
+	}
+	return nil
+}
+
+// Replenish applies a WINDOW_UPDATE increment of n bytes to streamID's
+// window, or to the connection window when streamID == StreamIDControl.
+func (fc *FlowController) Replenish(streamID uint32, n uint32) {
+	if streamID == StreamIDControl {
+		fc.conn.replenish(n)
+		return
+	}
+	fc.streamWindow(streamID).replenish(n)
+}
+
+// CloseStream drops a stream's windows once the stream is closed, so a late
+// WINDOW_UPDATE or DATA frame for it starts fresh state rather than
+// resurrecting old state.
+func (fc *FlowController) CloseStream(streamID uint32) {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	delete(fc.streams, streamID)
+	delete(fc.recvStreams, streamID)
+}
+
+// OnDataReceived accounts n bytes of inbound FrameData on streamID against the
+// receive-side credit fc has granted the peer for that stream and for the
+// connection, per "the receiver decrements both windows on data and emits
+// WINDOW_UPDATE when its window falls below half": whichever of the two
+// windows drops below half of initialWindow is topped back up to
+// initialWindow, and the corresponding WINDOW_UPDATE frame is included in the
+// returned slice for the caller to send back to the peer. Fails with
+// ErrCodeFlowControl if the peer sent more than it was granted.
+func (fc *FlowController) OnDataReceived(streamID uint32, n int) ([]*Frame, error) {
+	if streamID == StreamIDControl {
+		return nil, NewError(ErrCodeFlowControl, "control stream is not flow controlled")
+	}
+
+	var updates []*Frame
+
+	if increment, ok, err := fc.recvStreamWindow(streamID).consumeRecv(int64(n), fc.initialWindow); err != nil {
+		return nil, err
+	} else if ok {
+		f, err := EncodeWindowUpdate(streamID, increment)
+		if err != nil {
+			return nil, err
+		}
+		updates = append(updates, f)
+	}
+
+	if increment, ok, err := fc.recvConn.consumeRecv(int64(n), fc.initialWindow); err != nil {
+		return nil, err
+	} else if ok {
+		f, err := EncodeWindowUpdate(StreamIDControl, increment)
+		if err != nil {
+			return nil, err
+		}
+		updates = append(updates, f)
+	}
+
+	return updates, nil
+}
+
+// AvailableCredit returns streamID's currently available send credit, gated
+// by min(streamSendWindow, connSendWindow) - the same bound WriteDataFrame
+// uses to size the next chunk it sends.
+func (fc *FlowController) AvailableCredit(streamID uint32) int64 {
+	streamSize, _ := fc.streamWindow(streamID).snapshot()
+	connSize, _ := fc.conn.snapshot()
+	if streamSize < connSize {
+		return streamSize
+	}
+	return connSize
+}
+
+// WaitForCredit blocks until streamID has at least n bytes of both stream and
+// connection send credit, or ctx is done.
+func (fc *FlowController) WaitForCredit(ctx context.Context, streamID uint32, n int) error {
+	sw := fc.streamWindow(streamID)
+	for {
+		streamSize, streamNotify := sw.snapshot()
+		connSize, connNotify := fc.conn.snapshot()
+		if streamSize >= int64(n) && connSize >= int64(n) {
+			return nil
+		}
+
+		select {
+		case <-streamNotify:
+		case <-connNotify:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,220 @@
+package v1
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestWindowUpdateRoundTrip(t *testing.T) {
+	frame, err := EncodeWindowUpdate(3, 4096)
+	if err != nil {
+		t.Fatalf("EncodeWindowUpdate failed: %v", err)
+	}
+	if frame.Type != FrameWindowUpdate {
+		t.Fatalf("Type = %d, want FrameWindowUpdate", frame.Type)
+	}
+
+	increment, err := DecodeWindowUpdate(frame.Payload)
+	if err != nil {
+		t.Fatalf("DecodeWindowUpdate failed: %v", err)
+	}
+	if increment != 4096 {
+		t.Errorf("increment = %d, want 4096", increment)
+	}
+}
+
+func TestWindowUpdateRejectsZero(t *testing.T) {
+	if _, err := EncodeWindowUpdate(1, 0); err == nil {
+		t.Fatal("expected EncodeWindowUpdate to reject a zero increment")
+	}
+
+	_, err := DecodeWindowUpdate([]byte{0x00, 0x00, 0x00, 0x00})
+	if err == nil {
+		t.Fatal("expected DecodeWindowUpdate to reject a zero increment")
+	}
+	if pe, ok := IsProtocolError(err); !ok || pe.Code != ErrCodeBadPayload {
+		t.Errorf("expected ErrCodeBadPayload, got %v", err)
+	}
+}
+
+func TestFlowControllerExhaustion(t *testing.T) {
+	fc := NewFlowController(10)
+
+	if err := fc.Consume(1, 10); err != nil {
+		t.Fatalf("Consume within window failed: %v", err)
+	}
+
+	err := fc.Consume(1, 1)
+	if err == nil {
+		t.Fatal("expected Consume to fail once the window is exhausted")
+	}
+	if pe, ok := IsProtocolError(err); !ok || pe.Code != ErrCodeFlowControl {
+		t.Errorf("expected ErrCodeFlowControl, got %v", err)
+	}
+
+	fc.Replenish(1, 5)
+	fc.Replenish(StreamIDControl, 5)
+	if err := fc.Consume(1, 5); err != nil {
+		t.Fatalf("Consume after replenish failed: %v", err)
+	}
+}
+
+func TestFlowControllerConnWindowGatesAllStreams(t *testing.T) {
+	fc := NewFlowController(10)
+
+	if err := fc.Consume(1, 10); err != nil {
+		t.Fatalf("Consume failed: %v", err)
+	}
+
+	// Stream 2 has its own full window, but the connection window is now
+	// exhausted by stream 1's consumption.
+	if err := fc.Consume(2, 1); err == nil {
+		t.Fatal("expected connection window to gate stream 2 as well")
+	}
+}
+
+func TestFlowControllerOverflowClampsToMaxWindowSize(t *testing.T) {
+	fc := NewFlowController(DefaultInitialWindowSize)
+
+	fc.Replenish(1, MaxWindowSize)
+	fc.Replenish(1, MaxWindowSize)
+
+	sw := fc.streamWindow(1)
+	size, _ := sw.snapshot()
+	if size != MaxWindowSize {
+		t.Errorf("window size = %d, want clamp at MaxWindowSize (%d)", size, MaxWindowSize)
+	}
+}
+
+func TestFlowControllerClosedStreamUpdate(t *testing.T) {
+	fc := NewFlowController(DefaultInitialWindowSize)
+
+	_ = fc.streamWindow(1) // establish stream 1's window
+	fc.CloseStream(1)
+
+	// A WINDOW_UPDATE arriving after the stream closed starts a fresh window
+	// rather than panicking or resurrecting old state.
+	fc.Replenish(1, 100)
+	sw := fc.streamWindow(1)
+	size, _ := sw.snapshot()
+	if size != DefaultInitialWindowSize+100 {
+		t.Errorf("window size = %d, want %d", size, DefaultInitialWindowSize+100)
+	}
+}
+
+func TestFlowControllerWaitForCredit(t *testing.T) {
+	fc := NewFlowController(0)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- fc.WaitForCredit(ctx, 1, 10)
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+	fc.Replenish(StreamIDControl, 10)
+	fc.Replenish(1, 10)
+
+	if err := <-done; err != nil {
+		t.Fatalf("WaitForCredit failed: %v", err)
+	}
+}
+
+func TestFlowControllerWaitForCreditTimeout(t *testing.T) {
+	fc := NewFlowController(0)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	if err := fc.WaitForCredit(ctx, 1, 10); err == nil {
+		t.Fatal("expected WaitForCredit to time out with no credit available")
+	}
+}
+
+func TestFlowControllerOnDataReceivedEmitsWindowUpdateBelowHalf(t *testing.T) {
+	fc := NewFlowController(10)
+
+	// 4 bytes leaves 6/10 remaining - still at or above half, no update yet.
+	updates, err := fc.OnDataReceived(1, 4)
+	if err != nil {
+		t.Fatalf("OnDataReceived failed: %v", err)
+	}
+	if len(updates) != 0 {
+		t.Fatalf("got %d updates, want 0 while window is still >= half", len(updates))
+	}
+
+	// Another 2 bytes drops the stream and connection windows to 4/10, below
+	// half, so both should be topped back up to 10 and announced.
+	updates, err = fc.OnDataReceived(1, 2)
+	if err != nil {
+		t.Fatalf("OnDataReceived failed: %v", err)
+	}
+	if len(updates) != 2 {
+		t.Fatalf("got %d updates, want 2 (stream + connection)", len(updates))
+	}
+
+	var sawStream, sawConn bool
+	for _, f := range updates {
+		if f.Type != FrameWindowUpdate {
+			t.Errorf("update Type = %d, want FrameWindowUpdate", f.Type)
+		}
+		increment, err := DecodeWindowUpdate(f.Payload)
+		if err != nil {
+			t.Fatalf("DecodeWindowUpdate failed: %v", err)
+		}
+		if increment != 6 {
+			t.Errorf("increment = %d, want 6 (topped back up from 4 to 10)", increment)
+		}
+		switch f.StreamID {
+		case 1:
+			sawStream = true
+		case StreamIDControl:
+			sawConn = true
+		default:
+			t.Errorf("unexpected update StreamID %d", f.StreamID)
+		}
+	}
+	if !sawStream || !sawConn {
+		t.Errorf("expected one stream-level and one connection-level update, got %+v", updates)
+	}
+
+	sw := fc.recvStreamWindow(1)
+	size, _ := sw.snapshot()
+	if size != 10 {
+		t.Errorf("recv stream window = %d, want topped back up to 10", size)
+	}
+}
+
+func TestFlowControllerOnDataReceivedRejectsOverconsumption(t *testing.T) {
+	fc := NewFlowController(10)
+
+	_, err := fc.OnDataReceived(1, 11)
+	if err == nil {
+		t.Fatal("expected OnDataReceived to reject data exceeding the granted window")
+	}
+	if pe, ok := IsProtocolError(err); !ok || pe.Code != ErrCodeFlowControl {
+		t.Errorf("expected ErrCodeFlowControl, got %v", err)
+	}
+}
+
+func TestFlowControllerOnDataReceivedRejectsControlStream(t *testing.T) {
+	fc := NewFlowController(10)
+
+	if _, err := fc.OnDataReceived(StreamIDControl, 1); err == nil {
+		t.Fatal("expected OnDataReceived to reject the control stream")
+	}
+}
+
+func TestSplitForWindow(t *testing.T) {
+	payload := make([]byte, 25)
+	chunks := SplitForWindow(payload, 10)
+	if len(chunks) != 3 {
+		t.Fatalf("got %d chunks, want 3", len(chunks))
+	}
+	if len(chunks[0]) != 10 || len(chunks[1]) != 10 || len(chunks[2]) != 5 {
+		t.Errorf("unexpected chunk sizes: %d, %d, %d", len(chunks[0]), len(chunks[1]), len(chunks[2]))
+	}
+}
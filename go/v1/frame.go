@@ -3,6 +3,7 @@ package v1
 import (
 	"encoding/binary"
 	"io"
+	"sync"
 )
 
 type Frame struct {
@@ -11,6 +12,26 @@ type Frame struct {
 	Flags    uint8
 	StreamID uint32
 	Payload  []byte
+
+	// rawBuf/rawPool back a Frame returned by Framer.ReadFrame: Payload is a
+	// slice of rawBuf, which was leased from rawPool. Frames built by hand or
+	// returned by the package-level Decode leave rawPool nil, making Release a
+	// no-op.
+	rawBuf  []byte
+	rawPool *sync.Pool
+}
+
+// Release returns a Frame's backing buffer to the pool it was leased from.
+// Safe to call on any Frame; it is a no-op unless the Frame came from
+// Framer.ReadFrame. After Release, Payload must not be read.
+func (f *Frame) Release() {
+	if f.rawPool == nil {
+		return
+	}
+	releaseBuffer(f.rawBuf, f.rawPool)
+	f.rawBuf = nil
+	f.rawPool = nil
+	f.Payload = nil
 }
 
 func Encode(w io.Writer, f *Frame) error {
@@ -61,14 +82,51 @@ func Encode(w io.Writer, f *Frame) error {
 	return nil
 }
 
+// Decode reads a frame off the wire using the default MaxFrameSize limit.
+// Once a peer's SETTINGS have been negotiated, callers should use
+// DecodeWithLimit so the negotiated SettingMaxFrameSize supersedes the
+// package constant.
 func Decode(r io.Reader) (*Frame, error) {
-	var length uint32
-	if err := binary.Read(r, binary.BigEndian, &length); err != nil {
+	return DecodeWithLimit(r, MaxFrameSize)
+}
+
+// DecodeWithLimit is Decode but validates the frame length against
+// maxFrameSize instead of the MaxFrameSize constant. Use this once the peer
+// has negotiated a different SettingMaxFrameSize via SETTINGS.
+func DecodeWithLimit(r io.Reader, maxFrameSize uint32) (*Frame, error) {
+	return decodeFrame(r, maxFrameSize, nil, freshBuffer)
+}
+
+// bufferSource supplies decodeFrame with an n-byte buffer - either a one-off
+// allocation (freshBuffer, used by Decode/DecodeWithLimit) or a pooled lease
+// (acquireBuffer, used by Framer.ReadFrame) - plus the pool it came from, if
+// any, so the caller knows how to release it.
+type bufferSource func(n int) ([]byte, *sync.Pool)
+
+func freshBuffer(n int) ([]byte, *sync.Pool) {
+	return make([]byte, n), nil
+}
+
+// decodeFrame is the single wire-parsing routine behind Decode,
+// DecodeWithLimit, and Framer.ReadFrame, so the frame format and
+// ErrorCounter token set only need to be kept correct in one place. Every
+// parse failure invokes counter (falling back to DefaultErrorCounter) with a
+// stable token before returning - see ErrorCounter.
+func decodeFrame(r io.Reader, maxFrameSize uint32, counter ErrorCounter, getBuf bufferSource) (*Frame, error) {
+	var lengthBuf [4]byte
+	if _, err := io.ReadFull(r, lengthBuf[:]); err != nil {
+		countDecodeError(counter, "frame_short_read")
 		return nil, err
 	}
+	length := binary.BigEndian.Uint32(lengthBuf[:])
 
 	// Minimum frame size: Magic(2) + Version(1) + Type(1) + Flags(1) + StreamID(4) = HeaderSize bytes
-	if length < HeaderSize || length > MaxFrameSize {
+	if length < HeaderSize {
+		countDecodeError(counter, "frame_size_too_small")
+		return nil, NewError(ErrCodeBadFrame, "invalid frame size")
+	}
+	if length > maxFrameSize {
+		countDecodeError(counter, "frame_size_too_large")
 		return nil, NewError(ErrCodeBadFrame, "invalid frame size")
 	}
 
@@ -76,29 +134,32 @@ func Decode(r io.Reader) (*Frame, error) {
 	// Khi dùng thực tế, reader phải:
 	// - Gắn deadline: conn.SetReadDeadline(time.Now().Add(timeout))
 	// - Hoặc chạy trong goroutine có context với cancel
-	//
-	// NOTE: Hiện tại allocate buffer mỗi frame. Khi traffic lớn (hàng nghìn stream):
-	// - GC pressure tăng
-	// - Latency tăng
-	// Phase sau: nên dùng sync.Pool hoặc reuse buffer per-connection
-	buf := make([]byte, length)
+	buf, pool := getBuf(int(length))
 	if _, err := io.ReadFull(r, buf); err != nil {
+		releaseBuffer(buf, pool)
+		countDecodeError(counter, "frame_short_read")
 		return nil, err
 	}
 
 	// Validate magic marker: "RT" (0x52 0x54)
 	if buf[0] != Magic0 || buf[1] != Magic1 {
+		releaseBuffer(buf, pool)
+		countDecodeError(counter, "frame_bad_magic")
 		return nil, NewError(ErrCodeBadFrame, "invalid magic marker")
 	}
 
 	// Validate version
 	if buf[2] != Version {
+		releaseBuffer(buf, pool)
+		countDecodeError(counter, "frame_bad_version")
 		return nil, NewError(ErrCodeInvalidVersion, "invalid protocol version")
 	}
 
 	// Validate frame type
 	frameType := buf[3]
 	if !IsValidFrameType(frameType) {
+		releaseBuffer(buf, pool)
+		countDecodeError(counter, "frame_bad_type")
 		return nil, NewError(ErrCodeBadFrame, "invalid frame type")
 	}
 
@@ -116,6 +177,8 @@ func Decode(r io.Reader) (*Frame, error) {
 		Flags:    buf[4],
 		StreamID: streamID,
 		Payload:  buf[HeaderSize:],
+		rawBuf:   buf,
+		rawPool:  pool,
 	}, nil
 }
 
@@ -154,7 +217,7 @@ func (f *Frame) IsAck() bool {
 // IsValidFrameType kiểm tra frame type có hợp lệ không
 func IsValidFrameType(frameType uint8) bool {
 	switch frameType {
-	case FrameAuth, FrameOpenStream, FrameData, FrameClose, FrameHeartbeat:
+	case FrameAuth, FrameOpenStream, FrameData, FrameClose, FrameHeartbeat, FrameSettings, FrameWindowUpdate, FrameGoAway, FrameRstStream:
 		return true
 	default:
 		return false
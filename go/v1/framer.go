@@ -0,0 +1,204 @@
+package v1
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	"sync"
+)
+
+// pooledBuffer is one size bucket of reusable []byte buffers. Buffers are
+// pooled by pointer (*[]byte) to avoid the extra allocation sync.Pool incurs
+// when boxing a slice header into an interface{} directly.
+type pooledBuffer struct {
+	size int
+	pool sync.Pool
+}
+
+func newPooledBuffer(size int) *pooledBuffer {
+	pb := &pooledBuffer{size: size}
+	pb.pool.New = func() interface{} {
+		buf := make([]byte, size)
+		return &buf
+	}
+	return pb
+}
+
+// bufferBuckets holds the fixed size classes a Framer leases from. A frame
+// larger than the biggest bucket falls back to a one-off allocation.
+var bufferBuckets = []*pooledBuffer{
+	newPooledBuffer(1 * 1024),
+	newPooledBuffer(16 * 1024),
+	newPooledBuffer(256 * 1024),
+	newPooledBuffer(1024 * 1024),
+}
+
+// acquireBuffer returns an n-byte buffer from the smallest bucket that fits,
+// along with the pool it came from (nil if n exceeded every bucket and the
+// buffer was allocated one-off).
+func acquireBuffer(n int) ([]byte, *sync.Pool) {
+	for _, b := range bufferBuckets {
+		if n <= b.size {
+			ptr := b.pool.Get().(*[]byte)
+			return (*ptr)[:n], &b.pool
+		}
+	}
+	return make([]byte, n), nil
+}
+
+// releaseBuffer returns buf to pool. A nil pool (one-off allocation) is a
+// no-op.
+func releaseBuffer(buf []byte, pool *sync.Pool) {
+	if pool == nil {
+		return
+	}
+	full := buf[:cap(buf)]
+	pool.Put(&full)
+}
+
+// Framer wraps an io.ReadWriter and assembles whole frames into a single
+// pooled buffer per call, so WriteFrame issues exactly one Write (no torn
+// frames from interleaved partial writes) and ReadFrame avoids a fresh
+// allocation per frame.
+type Framer struct {
+	rw      io.ReadWriter
+	writeMu sync.Mutex
+
+	errorCounter ErrorCounter
+}
+
+// NewFramer wraps rw for frame-at-a-time reads and concurrency-safe writes.
+// ReadFrame takes no lock, so concurrent ReadFrame calls are not safe.
+func NewFramer(rw io.ReadWriter) *Framer {
+	return &Framer{rw: rw}
+}
+
+// SetErrorCounter installs c to observe ReadFrame's parse failures by token
+// (see ErrorCounter). Call before any concurrent ReadFrame use; it falls back
+// to DefaultErrorCounter when c is nil.
+func (fr *Framer) SetErrorCounter(c ErrorCounter) {
+	fr.errorCounter = c
+}
+
+// WriteFrame assembles f into a single pooled buffer and writes it under the
+// Framer's write lock, so concurrent WriteFrame calls from multiple
+// goroutines never interleave on the wire.
+func (fr *Framer) WriteFrame(f *Frame) error {
+	if f.Version != Version {
+		return NewError(ErrCodeInvalidVersion, "invalid protocol version")
+	}
+
+	length := uint32(HeaderSize + len(f.Payload))
+	if length > MaxFrameSize {
+		return NewError(ErrCodeFrameTooLarge, "frame too large")
+	}
+
+	buf, pool := acquireBuffer(4 + int(length))
+	defer releaseBuffer(buf, pool)
+
+	binary.BigEndian.PutUint32(buf[0:4], length)
+	buf[4] = Magic0
+	buf[5] = Magic1
+	buf[6] = f.Version
+	buf[7] = f.Type
+	buf[8] = f.Flags
+	binary.BigEndian.PutUint32(buf[9:13], f.StreamID)
+	copy(buf[13:], f.Payload)
+
+	fr.writeMu.Lock()
+	defer fr.writeMu.Unlock()
+	_, err := fr.rw.Write(buf)
+	return err
+}
+
+// WriteDataFrame writes payload on streamID as one or more FrameData frames,
+// gated by fc so it never sends more than min(streamSendWindow,
+// connSendWindow) credit's worth before blocking for a WINDOW_UPDATE: each
+// chunk waits on fc.WaitForCredit, is sized by SplitForWindow against
+// fc.AvailableCredit, and is deducted via fc.Consume before being written.
+// flags is applied only to the final chunk, so e.g. FlagEndStream lands on
+// the last frame of a split payload. An empty payload is written as a single
+// frame carrying flags and is not flow-controlled.
+//
+// Concurrent WriteDataFrame calls on the same streamID race between
+// WaitForCredit observing credit and Consume claiming it: if another
+// goroutine wins that race, Consume's failure just re-enters the wait
+// instead of aborting the send after chunks may already be on the wire.
+func (fr *Framer) WriteDataFrame(ctx context.Context, fc *FlowController, streamID uint32, payload []byte, flags uint8) error {
+	if streamID == StreamIDControl {
+		return NewError(ErrCodeFlowControl, "control stream is not flow controlled")
+	}
+	if len(payload) == 0 {
+		return fr.WriteFrame(&Frame{Version: Version, Type: FrameData, Flags: flags, StreamID: streamID})
+	}
+
+	remaining := payload
+	for len(remaining) > 0 {
+		var chunk []byte
+		for {
+			if err := fc.WaitForCredit(ctx, streamID, 1); err != nil {
+				return err
+			}
+
+			chunk = SplitForWindow(remaining, int(fc.AvailableCredit(streamID)))[0]
+			if err := fc.Consume(streamID, len(chunk)); err != nil {
+				continue
+			}
+			break
+		}
+		remaining = remaining[len(chunk):]
+
+		chunkFlags := FlagNone
+		if len(remaining) == 0 {
+			chunkFlags = flags
+		}
+
+		if err := fr.WriteFrame(&Frame{
+			Version:  Version,
+			Type:     FrameData,
+			Flags:    chunkFlags,
+			StreamID: streamID,
+			Payload:  chunk,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReadFrame reads the length prefix, leases a right-sized buffer from the
+// pool, and reads the rest of the frame into it. The returned Frame's
+// Payload aliases that buffer; call Frame.Release when done with it to
+// return the buffer to the pool. Parsing and ErrorCounter token assignment
+// are shared with Decode/DecodeWithLimit via decodeFrame.
+func (fr *Framer) ReadFrame() (*Frame, error) {
+	return decodeFrame(fr.rw, MaxFrameSize, fr.errorCounter, acquireBuffer)
+}
+
+// ReadDataFrame reads the next frame via ReadFrame and, if it is a FrameData
+// frame, accounts the payload against fc's receive-side window via
+// FlowController.OnDataReceived, writing out any resulting WINDOW_UPDATE
+// frames back to the peer before returning. Frames of any other type pass
+// through unaccounted, since only DATA consumes receive credit.
+func (fr *Framer) ReadDataFrame(fc *FlowController) (*Frame, error) {
+	f, err := fr.ReadFrame()
+	if err != nil {
+		return nil, err
+	}
+	if f.Type != FrameData {
+		return f, nil
+	}
+
+	updates, err := fc.OnDataReceived(f.StreamID, len(f.Payload))
+	if err != nil {
+		f.Release()
+		return nil, err
+	}
+	for _, u := range updates {
+		if err := fr.WriteFrame(u); err != nil {
+			f.Release()
+			return nil, err
+		}
+	}
+	return f, nil
+}
@@ -0,0 +1,404 @@
+package v1
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestFramerRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	fr := NewFramer(&buf)
+
+	frame := &Frame{
+		Version:  Version,
+		Type:     FrameData,
+		Flags:    FlagEndStream,
+		StreamID: 7,
+		Payload:  []byte("hello framer"),
+	}
+
+	if err := fr.WriteFrame(frame); err != nil {
+		t.Fatalf("WriteFrame failed: %v", err)
+	}
+
+	got, err := fr.ReadFrame()
+	if err != nil {
+		t.Fatalf("ReadFrame failed: %v", err)
+	}
+	defer got.Release()
+
+	if got.Type != frame.Type || got.Flags != frame.Flags || got.StreamID != frame.StreamID {
+		t.Fatalf("header mismatch: got %+v", got)
+	}
+	if !bytes.Equal(got.Payload, frame.Payload) {
+		t.Errorf("Payload = %q, want %q", got.Payload, frame.Payload)
+	}
+}
+
+func TestFramerLargeFramePoolFallback(t *testing.T) {
+	var buf bytes.Buffer
+	fr := NewFramer(&buf)
+
+	frame := &Frame{
+		Version:  Version,
+		Type:     FrameData,
+		StreamID: 1,
+		Payload:  make([]byte, 2*1024*1024), // bigger than the largest bucket
+	}
+
+	if err := fr.WriteFrame(frame); err != nil {
+		t.Fatalf("WriteFrame failed: %v", err)
+	}
+
+	got, err := fr.ReadFrame()
+	if err != nil {
+		t.Fatalf("ReadFrame failed: %v", err)
+	}
+	defer got.Release()
+
+	if len(got.Payload) != len(frame.Payload) {
+		t.Errorf("Payload len = %d, want %d", len(got.Payload), len(frame.Payload))
+	}
+}
+
+// pipeConn is a minimal in-memory io.ReadWriter backed by an io.Pipe, used so
+// concurrent writers genuinely race on the same wire instead of a buffer that
+// serializes access itself.
+type pipeConn struct {
+	r *io.PipeReader
+	w *io.PipeWriter
+}
+
+func newPipeConn() *pipeConn {
+	r, w := io.Pipe()
+	return &pipeConn{r: r, w: w}
+}
+
+func (p *pipeConn) Read(b []byte) (int, error)  { return p.r.Read(b) }
+func (p *pipeConn) Write(b []byte) (int, error) { return p.w.Write(b) }
+
+func TestFramerConcurrentWritesNeverInterleave(t *testing.T) {
+	conn := newPipeConn()
+	fr := NewFramer(conn)
+
+	const goroutines = 8
+	const framesEach = 50
+
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func(id uint32) {
+			defer wg.Done()
+			payload := bytes.Repeat([]byte{byte(id)}, 100)
+			for i := 0; i < framesEach; i++ {
+				err := fr.WriteFrame(&Frame{
+					Version:  Version,
+					Type:     FrameData,
+					StreamID: id,
+					Payload:  payload,
+				})
+				if err != nil {
+					t.Errorf("WriteFrame failed: %v", err)
+				}
+			}
+		}(uint32(g + 1))
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		conn.w.Close()
+		close(done)
+	}()
+
+	for i := 0; i < goroutines*framesEach; i++ {
+		f, err := fr.ReadFrame()
+		if err != nil {
+			t.Fatalf("ReadFrame failed: %v", err)
+		}
+		want := byte(f.StreamID)
+		for _, b := range f.Payload {
+			if b != want {
+				t.Fatalf("torn frame: stream %d payload contains byte %d", f.StreamID, b)
+			}
+		}
+		f.Release()
+	}
+
+	<-done
+}
+
+// TestFramerWriteDataFrameGatedByWindow drives WriteDataFrame against a
+// FlowController whose window is much smaller than the payload, with the
+// reader granting credit back one chunk at a time (mirroring a peer's
+// WINDOW_UPDATE after consuming each DATA frame). It asserts the payload
+// comes out split into multiple frames no larger than the window, reassembles
+// correctly, and that flags lands only on the final chunk.
+func TestFramerWriteDataFrameGatedByWindow(t *testing.T) {
+	const window = 10
+	conn := newPipeConn()
+	writer := NewFramer(conn)
+	reader := NewFramer(conn)
+	fc := NewFlowController(window)
+
+	payload := bytes.Repeat([]byte{0xCD}, 25)
+
+	writeErr := make(chan error, 1)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		writeErr <- writer.WriteDataFrame(ctx, fc, 1, payload, FlagEndStream)
+	}()
+
+	var got []byte
+	var chunkSizes []int
+	for len(got) < len(payload) {
+		f, err := reader.ReadFrame()
+		if err != nil {
+			t.Fatalf("ReadFrame failed: %v", err)
+		}
+		chunkSizes = append(chunkSizes, len(f.Payload))
+		got = append(got, f.Payload...)
+
+		isLast := len(got) == len(payload)
+		if f.IsEndStream() != isLast {
+			t.Errorf("chunk %d: IsEndStream = %v, want %v", len(chunkSizes), f.IsEndStream(), isLast)
+		}
+
+		// Grant back exactly what this chunk consumed, as a peer's
+		// WINDOW_UPDATE would, so WriteDataFrame can make further progress.
+		fc.Replenish(1, uint32(len(f.Payload)))
+		fc.Replenish(StreamIDControl, uint32(len(f.Payload)))
+		f.Release()
+	}
+
+	if err := <-writeErr; err != nil {
+		t.Fatalf("WriteDataFrame failed: %v", err)
+	}
+	if !bytes.Equal(got, payload) {
+		t.Fatalf("reassembled payload mismatch")
+	}
+	if len(chunkSizes) < 3 {
+		t.Errorf("expected payload to be split across multiple chunks, got %v", chunkSizes)
+	}
+	for _, n := range chunkSizes {
+		if n > window {
+			t.Errorf("chunk size %d exceeds window %d", n, window)
+		}
+	}
+}
+
+// TestFramerWriteDataFrameBlocksUntilCredit checks that WriteDataFrame
+// actually blocks on an exhausted window rather than sending past it, and
+// that ctx cancellation unblocks and surfaces an error instead of hanging
+// forever.
+func TestFramerWriteDataFrameBlocksUntilCredit(t *testing.T) {
+	var buf bytes.Buffer
+	fr := NewFramer(&buf)
+	fc := NewFlowController(0)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	err := fr.WriteDataFrame(ctx, fc, 1, []byte("blocked"), FlagEndStream)
+	if err == nil {
+		t.Fatal("expected WriteDataFrame to fail once ctx is done")
+	}
+	if buf.Len() != 0 {
+		t.Errorf("expected no frame written while window is exhausted, wrote %d bytes", buf.Len())
+	}
+}
+
+func TestFramerWriteDataFrameEmptyPayloadBypassesFlowControl(t *testing.T) {
+	var buf bytes.Buffer
+	fr := NewFramer(&buf)
+	fc := NewFlowController(0)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	if err := fr.WriteDataFrame(ctx, fc, 1, nil, FlagEndStream); err != nil {
+		t.Fatalf("WriteDataFrame failed: %v", err)
+	}
+
+	f, err := Decode(&buf)
+	if err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+	if f.Type != FrameData || !f.IsEndStream() {
+		t.Errorf("got Type=%d IsEndStream=%v, want FrameData with FlagEndStream", f.Type, f.IsEndStream())
+	}
+}
+
+func TestFramerWriteDataFrameRejectsControlStream(t *testing.T) {
+	var buf bytes.Buffer
+	fr := NewFramer(&buf)
+	fc := NewFlowController(10)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	if err := fr.WriteDataFrame(ctx, fc, StreamIDControl, []byte("x"), FlagNone); err == nil {
+		t.Fatal("expected WriteDataFrame to reject the control stream")
+	}
+}
+
+// TestFramerWriteDataFrameConcurrentSameStream drives two WriteDataFrame
+// calls on the same streamID against a FlowController with just enough total
+// credit for both, so their WaitForCredit/AvailableCredit/Consume sequences
+// race over the same window. Neither call should abort with a terminal error
+// from a lost race - WriteDataFrame must re-wait and retry instead.
+func TestFramerWriteDataFrameConcurrentSameStream(t *testing.T) {
+	conn := newPipeConn()
+	writer := NewFramer(conn)
+	reader := NewFramer(conn)
+	fc := NewFlowController(4)
+
+	payloadA := bytes.Repeat([]byte{0xAA}, 20)
+	payloadB := bytes.Repeat([]byte{0xBB}, 20)
+
+	var wg sync.WaitGroup
+	results := make(chan error, 2)
+	for _, p := range [][]byte{payloadA, payloadB} {
+		wg.Add(1)
+		go func(payload []byte) {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+			defer cancel()
+			results <- writer.WriteDataFrame(ctx, fc, 1, payload, FlagNone)
+		}(p)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		conn.w.Close()
+		close(done)
+	}()
+
+	gotA, gotB := 0, 0
+	for {
+		f, err := reader.ReadFrame()
+		if err != nil {
+			break
+		}
+		for _, b := range f.Payload {
+			switch b {
+			case 0xAA:
+				gotA++
+			case 0xBB:
+				gotB++
+			default:
+				t.Fatalf("unexpected byte %#x in payload", b)
+			}
+		}
+		// Grant back exactly what this chunk consumed, as a peer's
+		// WINDOW_UPDATE would, so both writers can keep making progress.
+		fc.Replenish(1, uint32(len(f.Payload)))
+		fc.Replenish(StreamIDControl, uint32(len(f.Payload)))
+		f.Release()
+	}
+	<-done
+
+	for i := 0; i < 2; i++ {
+		if err := <-results; err != nil {
+			t.Fatalf("WriteDataFrame failed: %v", err)
+		}
+	}
+	if gotA != len(payloadA) || gotB != len(payloadB) {
+		t.Fatalf("got %d bytes of A, %d bytes of B, want %d and %d", gotA, gotB, len(payloadA), len(payloadB))
+	}
+}
+
+// TestFramerReadDataFrameEmitsWindowUpdateBelowHalf checks that ReadDataFrame
+// wires the receive side into the actual read path: once enough DATA has come
+// in to drop the window below half, it writes a WINDOW_UPDATE back over the
+// same Framer before returning the DATA frame to the caller.
+func TestFramerReadDataFrameEmitsWindowUpdateBelowHalf(t *testing.T) {
+	var buf bytes.Buffer
+	fr := NewFramer(&buf)
+	fc := NewFlowController(10)
+
+	if err := fr.WriteFrame(&Frame{Version: Version, Type: FrameData, StreamID: 1, Payload: bytes.Repeat([]byte{0x11}, 6)}); err != nil {
+		t.Fatalf("WriteFrame failed: %v", err)
+	}
+
+	// Consuming the DATA frame drops the window below half, so
+	// ReadDataFrame writes a WINDOW_UPDATE back onto the same buf before
+	// returning - read that off next.
+	f, err := fr.ReadDataFrame(fc)
+	if err != nil {
+		t.Fatalf("ReadDataFrame failed: %v", err)
+	}
+	if len(f.Payload) != 6 {
+		t.Fatalf("Payload len = %d, want 6", len(f.Payload))
+	}
+	f.Release()
+
+	update, err := fr.ReadFrame()
+	if err != nil {
+		t.Fatalf("expected a WINDOW_UPDATE to come back, ReadFrame failed: %v", err)
+	}
+	if update.Type != FrameWindowUpdate {
+		t.Fatalf("Type = %d, want FrameWindowUpdate", update.Type)
+	}
+}
+
+func TestFramerReadDataFramePassesThroughNonDataFrames(t *testing.T) {
+	var buf bytes.Buffer
+	fr := NewFramer(&buf)
+	fc := NewFlowController(10)
+
+	if err := fr.WriteFrame(&Frame{Version: Version, Type: FrameHeartbeat, StreamID: StreamIDControl}); err != nil {
+		t.Fatalf("WriteFrame failed: %v", err)
+	}
+
+	f, err := fr.ReadDataFrame(fc)
+	if err != nil {
+		t.Fatalf("ReadDataFrame failed: %v", err)
+	}
+	if f.Type != FrameHeartbeat {
+		t.Errorf("Type = %d, want FrameHeartbeat (pass-through)", f.Type)
+	}
+}
+
+func BenchmarkFramerWriteRead(b *testing.B) {
+	var buf bytes.Buffer
+	fr := NewFramer(&buf)
+	frame := &Frame{
+		Version:  Version,
+		Type:     FrameData,
+		StreamID: 1,
+		Payload:  make([]byte, 1024),
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		buf.Reset()
+		_ = fr.WriteFrame(frame)
+		f, _ := fr.ReadFrame()
+		f.Release()
+	}
+}
+
+func BenchmarkPackageEncodeDecode(b *testing.B) {
+	frame := &Frame{
+		Version:  Version,
+		Type:     FrameData,
+		StreamID: 1,
+		Payload:  make([]byte, 1024),
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var buf bytes.Buffer
+		_ = Encode(&buf, frame)
+		_, _ = Decode(&buf)
+	}
+}
@@ -0,0 +1,82 @@
+package v1
+
+import (
+	"encoding/binary"
+	"sync"
+)
+
+// goAwayHeaderSize: LastStreamID(4) + ErrorCode(2) = 6 bytes before the
+// variable-length debug data.
+const goAwayHeaderSize = 6
+
+// GoAwayPayload is the parsed payload of a GOAWAY frame. The sender promises
+// to finish processing every stream with ID <= LastStreamID and refuses any
+// FrameOpenStream above it with ErrCodeStreamRefused.
+type GoAwayPayload struct {
+	LastStreamID uint32
+	Code         ErrorCode
+	Debug        []byte
+}
+
+// EncodeGoAway builds a GOAWAY frame on the control stream announcing that
+// the sender will not accept new streams above lastID.
+func EncodeGoAway(code ErrorCode, lastID uint32, debug string) *Frame {
+	payload := make([]byte, goAwayHeaderSize+len(debug))
+	binary.BigEndian.PutUint32(payload[0:4], lastID)
+	binary.BigEndian.PutUint16(payload[4:6], uint16(code))
+	copy(payload[goAwayHeaderSize:], debug)
+
+	return &Frame{
+		Version:  Version,
+		Type:     FrameGoAway,
+		Flags:    FlagNone,
+		StreamID: StreamIDControl,
+		Payload:  payload,
+	}
+}
+
+// DecodeGoAwayPayload parses a GOAWAY payload.
+func DecodeGoAwayPayload(payload []byte) (*GoAwayPayload, error) {
+	if len(payload) < goAwayHeaderSize {
+		return nil, NewError(ErrCodeBadPayload, "goaway payload too short")
+	}
+
+	return &GoAwayPayload{
+		LastStreamID: binary.BigEndian.Uint32(payload[0:4]),
+		Code:         ErrorCode(binary.BigEndian.Uint16(payload[4:6])),
+		Debug:        append([]byte(nil), payload[goAwayHeaderSize:]...),
+	}, nil
+}
+
+// GoAwayTracker enforces the monotonic-tightening rule for an incoming
+// sequence of GOAWAY frames: a peer may send a follow-up GOAWAY narrowing
+// LastStreamID further as draining progresses, but must never raise it, the
+// same convention HTTP/2 uses.
+type GoAwayTracker struct {
+	mu           sync.Mutex
+	received     bool
+	lastStreamID uint32
+}
+
+// Accept records p as the latest GOAWAY seen from the peer, rejecting it if
+// it would raise LastStreamID above a previously accepted value.
+func (t *GoAwayTracker) Accept(p *GoAwayPayload) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.received && p.LastStreamID > t.lastStreamID {
+		return NewError(ErrCodeBadFrame, "goaway LastStreamID must not increase")
+	}
+
+	t.received = true
+	t.lastStreamID = p.LastStreamID
+	return nil
+}
+
+// LastStreamID returns the most recently accepted LastStreamID and whether
+// any GOAWAY has been accepted yet.
+func (t *GoAwayTracker) LastStreamID() (uint32, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.lastStreamID, t.received
+}
@@ -0,0 +1,99 @@
+package v1
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestGoAwayRoundTrip(t *testing.T) {
+	frame := EncodeGoAway(ErrCodeStreamRefused, 41, "draining for deploy")
+	if frame.Type != FrameGoAway {
+		t.Fatalf("Type = %d, want FrameGoAway", frame.Type)
+	}
+	if frame.StreamID != StreamIDControl {
+		t.Fatalf("StreamID = %d, want StreamIDControl", frame.StreamID)
+	}
+
+	var buf bytes.Buffer
+	if err := Encode(&buf, frame); err != nil {
+		t.Fatalf("Encode failed: %v", err)
+	}
+	decoded, err := Decode(&buf)
+	if err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+
+	payload, err := DecodeGoAwayPayload(decoded.Payload)
+	if err != nil {
+		t.Fatalf("DecodeGoAwayPayload failed: %v", err)
+	}
+	if payload.LastStreamID != 41 {
+		t.Errorf("LastStreamID = %d, want 41", payload.LastStreamID)
+	}
+	if payload.Code != ErrCodeStreamRefused {
+		t.Errorf("Code = %d, want ErrCodeStreamRefused", payload.Code)
+	}
+	if string(payload.Debug) != "draining for deploy" {
+		t.Errorf("Debug = %q, want %q", payload.Debug, "draining for deploy")
+	}
+}
+
+func TestGoAwayRefuseEverything(t *testing.T) {
+	frame := EncodeGoAway(ErrCodeStreamRefused, 0, "")
+	payload, err := DecodeGoAwayPayload(frame.Payload)
+	if err != nil {
+		t.Fatalf("DecodeGoAwayPayload failed: %v", err)
+	}
+	if payload.LastStreamID != 0 {
+		t.Errorf("LastStreamID = %d, want 0 (refuse everything)", payload.LastStreamID)
+	}
+	if len(payload.Debug) != 0 {
+		t.Errorf("Debug = %q, want empty", payload.Debug)
+	}
+}
+
+func TestGoAwayDebugFieldRoundTrip(t *testing.T) {
+	debug := "peer requested graceful shutdown at 2026-07-27T12:00:00Z"
+	frame := EncodeGoAway(ErrCodeUnknown, 100, debug)
+	payload, err := DecodeGoAwayPayload(frame.Payload)
+	if err != nil {
+		t.Fatalf("DecodeGoAwayPayload failed: %v", err)
+	}
+	if string(payload.Debug) != debug {
+		t.Errorf("Debug = %q, want %q", payload.Debug, debug)
+	}
+}
+
+func TestGoAwayTrackerMonotonicTightening(t *testing.T) {
+	var tracker GoAwayTracker
+
+	if err := tracker.Accept(&GoAwayPayload{LastStreamID: 100}); err != nil {
+		t.Fatalf("first Accept failed: %v", err)
+	}
+
+	// A second GOAWAY with a lower LastStreamID tightens the boundary and
+	// must be accepted.
+	if err := tracker.Accept(&GoAwayPayload{LastStreamID: 50}); err != nil {
+		t.Fatalf("tightening Accept failed: %v", err)
+	}
+
+	last, ok := tracker.LastStreamID()
+	if !ok || last != 50 {
+		t.Fatalf("LastStreamID() = %d, %v; want 50, true", last, ok)
+	}
+
+	// Raising LastStreamID above a previously accepted value is invalid.
+	if err := tracker.Accept(&GoAwayPayload{LastStreamID: 75}); err == nil {
+		t.Fatal("expected Accept to reject a LastStreamID increase")
+	}
+}
+
+func TestGoAwayBadPayload(t *testing.T) {
+	_, err := DecodeGoAwayPayload([]byte{0x00, 0x01})
+	if err == nil {
+		t.Fatal("expected error for too-short goaway payload")
+	}
+	if pe, ok := IsProtocolError(err); !ok || pe.Code != ErrCodeBadPayload {
+		t.Errorf("expected ErrCodeBadPayload, got %v", err)
+	}
+}
@@ -0,0 +1,139 @@
+package v1
+
+import (
+	"context"
+	"encoding/binary"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// heartbeatPayloadSize: FrameHeartbeat always carries an opaque 8-byte nonce
+// chosen by the sender, echoed back unmodified (with FlagAck set) by the
+// receiver so RTT can be measured and a half-open peer detected.
+const heartbeatPayloadSize = 8
+
+// EncodeHeartbeat builds a FrameHeartbeat carrying nonce as its 8-byte
+// opaque payload.
+func EncodeHeartbeat(nonce uint64) *Frame {
+	payload := make([]byte, heartbeatPayloadSize)
+	binary.BigEndian.PutUint64(payload, nonce)
+
+	return &Frame{
+		Version:  Version,
+		Type:     FrameHeartbeat,
+		Flags:    FlagNone,
+		StreamID: StreamIDControl,
+		Payload:  payload,
+	}
+}
+
+// AckHeartbeat builds the FlagAck reply echoing nonce back to the sender.
+func AckHeartbeat(nonce uint64) *Frame {
+	frame := EncodeHeartbeat(nonce)
+	frame.Flags = FlagAck
+	return frame
+}
+
+// DecodeHeartbeatPayload parses a FrameHeartbeat payload, rejecting anything
+// other than exactly 8 bytes with ErrCodeBadPayload.
+func DecodeHeartbeatPayload(payload []byte) (uint64, error) {
+	if len(payload) != heartbeatPayloadSize {
+		return 0, NewError(ErrCodeBadPayload, "heartbeat payload must be 8 bytes")
+	}
+	return binary.BigEndian.Uint64(payload), nil
+}
+
+type pendingPing struct {
+	sentAt time.Time
+	done   chan pingResult
+}
+
+type pingResult struct {
+	rtt time.Duration
+	err error
+}
+
+// Pinger sends heartbeat frames and measures round-trip time by tracking
+// outstanding nonces until their FlagAck reply arrives or a deadline passes.
+type Pinger struct {
+	send    func(*Frame) error
+	timeout time.Duration
+
+	nonceCounter uint64
+
+	mu          sync.Mutex
+	outstanding map[uint64]pendingPing
+}
+
+// NewPinger returns a Pinger that writes heartbeat frames via send and times
+// out a Ping that receives no ACK within timeout.
+func NewPinger(send func(*Frame) error, timeout time.Duration) *Pinger {
+	return &Pinger{
+		send:        send,
+		timeout:     timeout,
+		outstanding: make(map[uint64]pendingPing),
+	}
+}
+
+// Ping sends a heartbeat with a fresh nonce and blocks until its ACK arrives
+// (via HandleAck), the Pinger's timeout elapses, or ctx is done. Concurrent
+// Ping calls use distinct nonces and never collide.
+func (p *Pinger) Ping(ctx context.Context) (time.Duration, error) {
+	nonce := atomic.AddUint64(&p.nonceCounter, 1)
+	done := make(chan pingResult, 1)
+
+	p.mu.Lock()
+	p.outstanding[nonce] = pendingPing{sentAt: time.Now(), done: done}
+	p.mu.Unlock()
+
+	defer func() {
+		p.mu.Lock()
+		delete(p.outstanding, nonce)
+		p.mu.Unlock()
+	}()
+
+	if err := p.send(EncodeHeartbeat(nonce)); err != nil {
+		return 0, err
+	}
+
+	timer := time.NewTimer(p.timeout)
+	defer timer.Stop()
+
+	select {
+	case res := <-done:
+		return res.rtt, res.err
+	case <-timer.C:
+		return 0, NewError(ErrCodeUnknown, "heartbeat timed out waiting for ack")
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	}
+}
+
+// HandleAck feeds an incoming FlagAck heartbeat frame to the Pinger,
+// completing the matching Ping call. A nonce with no matching outstanding
+// Ping (already timed out, or never sent) is surfaced as an error.
+func (p *Pinger) HandleAck(frame *Frame) error {
+	if !frame.IsAck() {
+		return NewError(ErrCodeBadFrame, "heartbeat frame missing FlagAck")
+	}
+
+	nonce, err := DecodeHeartbeatPayload(frame.Payload)
+	if err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	pending, ok := p.outstanding[nonce]
+	if ok {
+		delete(p.outstanding, nonce)
+	}
+	p.mu.Unlock()
+
+	if !ok {
+		return NewError(ErrCodeUnknown, "heartbeat ack nonce mismatch")
+	}
+
+	pending.done <- pingResult{rtt: time.Since(pending.sentAt)}
+	return nil
+}
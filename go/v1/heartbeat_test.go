@@ -0,0 +1,143 @@
+package v1
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestHeartbeatRoundTrip(t *testing.T) {
+	const want uint64 = 0xdeadbeefcafef00d
+	frame := EncodeHeartbeat(want)
+	nonce, err := DecodeHeartbeatPayload(frame.Payload)
+	if err != nil {
+		t.Fatalf("DecodeHeartbeatPayload failed: %v", err)
+	}
+	if nonce != want {
+		t.Errorf("nonce = %#x, want %#x", nonce, want)
+	}
+}
+
+func TestHeartbeatBadPayloadLength(t *testing.T) {
+	_, err := DecodeHeartbeatPayload([]byte{0x01, 0x02, 0x03})
+	if err == nil {
+		t.Fatal("expected error for wrong-length heartbeat payload")
+	}
+	if pe, ok := IsProtocolError(err); !ok || pe.Code != ErrCodeBadPayload {
+		t.Errorf("expected ErrCodeBadPayload, got %v", err)
+	}
+}
+
+func TestAckHeartbeatRequiresAckFlag(t *testing.T) {
+	ack := AckHeartbeat(42)
+	if !ack.IsAck() {
+		t.Fatal("expected ack heartbeat frame to carry FlagAck")
+	}
+
+	reply, err := DecodeHeartbeatPayload(ack.Payload)
+	if err != nil {
+		t.Fatalf("DecodeHeartbeatPayload failed: %v", err)
+	}
+	if reply != 42 {
+		t.Errorf("echoed nonce = %d, want 42", reply)
+	}
+}
+
+func TestPingerRoundTrip(t *testing.T) {
+	var pinger *Pinger
+	pinger = NewPinger(func(f *Frame) error {
+		nonce, err := DecodeHeartbeatPayload(f.Payload)
+		if err != nil {
+			return err
+		}
+		go func() {
+			_ = pinger.HandleAck(AckHeartbeat(nonce))
+		}()
+		return nil
+	}, time.Second)
+
+	rtt, err := pinger.Ping(context.Background())
+	if err != nil {
+		t.Fatalf("Ping failed: %v", err)
+	}
+	if rtt < 0 {
+		t.Errorf("rtt = %v, want >= 0", rtt)
+	}
+}
+
+func TestPingerHandleAckRequiresAckFlag(t *testing.T) {
+	pinger := NewPinger(func(f *Frame) error { return nil }, 200*time.Millisecond)
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := pinger.Ping(context.Background())
+		done <- err
+	}()
+
+	// A fresh (non-ack) heartbeat from the peer can legitimately carry the
+	// same nonce as our own outstanding ping - nonces are independent per
+	// direction on a bidirectional heartbeat channel. It must not be
+	// mistaken for our ping's ACK.
+	if err := pinger.HandleAck(EncodeHeartbeat(1)); err == nil {
+		t.Fatal("expected HandleAck to reject a heartbeat without FlagAck")
+	}
+
+	select {
+	case err := <-done:
+		t.Fatalf("Ping completed unexpectedly with err=%v; a non-ack heartbeat must not complete it", err)
+	case <-time.After(50 * time.Millisecond):
+		// Expected: the outstanding ping is still pending.
+	}
+}
+
+func TestPingerMismatchedNonce(t *testing.T) {
+	pinger := NewPinger(func(f *Frame) error { return nil }, 50*time.Millisecond)
+
+	err := pinger.HandleAck(AckHeartbeat(999))
+	if err == nil {
+		t.Fatal("expected error for ack with no matching outstanding ping")
+	}
+}
+
+func TestPingerTimeout(t *testing.T) {
+	pinger := NewPinger(func(f *Frame) error { return nil }, 20*time.Millisecond)
+
+	_, err := pinger.Ping(context.Background())
+	if err == nil {
+		t.Fatal("expected Ping to time out with no ack ever sent")
+	}
+}
+
+func TestPingerConcurrentPingsDontCollide(t *testing.T) {
+	var pinger *Pinger
+	pinger = NewPinger(func(f *Frame) error {
+		nonce, err := DecodeHeartbeatPayload(f.Payload)
+		if err != nil {
+			return err
+		}
+		go func() {
+			_ = pinger.HandleAck(AckHeartbeat(nonce))
+		}()
+		return nil
+	}, time.Second)
+
+	const n = 20
+	var wg sync.WaitGroup
+	errs := make(chan error, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := pinger.Ping(context.Background()); err != nil {
+				errs <- err
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Errorf("concurrent Ping failed: %v", err)
+	}
+}
@@ -0,0 +1,65 @@
+package v1
+
+import (
+	"encoding/binary"
+	"unicode/utf8"
+)
+
+// rstStreamHeaderSize: ErrorCode(2) precedes the variable-length UTF-8
+// message.
+const rstStreamHeaderSize = 2
+
+// MaxRstStreamMessage bounds the diagnostic message carried on a RST_STREAM
+// frame; longer messages are truncated by EncodeRstStream rather than
+// growing the frame without limit.
+const MaxRstStreamMessage = 4096
+
+// EncodeRstStream builds a FrameRstStream aborting streamID with code and an
+// optional diagnostic message. streamID == StreamIDControl signals a
+// connection-level error that should precede a GOAWAY; streamID > 0 aborts
+// just that one stream. On receipt, higher layers transition the stream
+// directly to closed without waiting for a FIN. msg longer than
+// MaxRstStreamMessage is truncated.
+func EncodeRstStream(streamID uint32, code ErrorCode, msg string) *Frame {
+	if len(msg) > MaxRstStreamMessage {
+		msg = truncateRuneBoundary(msg, MaxRstStreamMessage)
+	}
+
+	payload := make([]byte, rstStreamHeaderSize+len(msg))
+	binary.BigEndian.PutUint16(payload[0:2], uint16(code))
+	copy(payload[rstStreamHeaderSize:], msg)
+
+	return &Frame{
+		Version:  Version,
+		Type:     FrameRstStream,
+		Flags:    FlagNone,
+		StreamID: streamID,
+		Payload:  payload,
+	}
+}
+
+// DecodeRstStreamPayload parses a RST_STREAM payload into its error code and
+// UTF-8 message, rejecting payloads shorter than rstStreamHeaderSize with
+// ErrCodeBadPayload.
+func DecodeRstStreamPayload(p []byte) (ErrorCode, string, error) {
+	if len(p) < rstStreamHeaderSize {
+		return 0, "", NewError(ErrCodeBadPayload, "rst_stream payload too short")
+	}
+
+	code := ErrorCode(binary.BigEndian.Uint16(p[0:2]))
+	msg := string(p[rstStreamHeaderSize:])
+	return code, msg, nil
+}
+
+// truncateRuneBoundary truncates s to at most n bytes, backing up to the
+// start of a rune if n would otherwise land inside a multi-byte UTF-8
+// sequence, so the result is always valid UTF-8.
+func truncateRuneBoundary(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
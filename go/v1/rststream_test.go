@@ -0,0 +1,130 @@
+package v1
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestRstStreamRoundTrip(t *testing.T) {
+	frame := EncodeRstStream(7, ErrCodeStreamClosed, "peer aborted")
+	if frame.Type != FrameRstStream {
+		t.Fatalf("Type = %d, want FrameRstStream", frame.Type)
+	}
+
+	var buf bytes.Buffer
+	if err := Encode(&buf, frame); err != nil {
+		t.Fatalf("Encode failed: %v", err)
+	}
+	decoded, err := Decode(&buf)
+	if err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+
+	code, msg, err := DecodeRstStreamPayload(decoded.Payload)
+	if err != nil {
+		t.Fatalf("DecodeRstStreamPayload failed: %v", err)
+	}
+	if code != ErrCodeStreamClosed {
+		t.Errorf("code = %d, want ErrCodeStreamClosed", code)
+	}
+	if msg != "peer aborted" {
+		t.Errorf("msg = %q, want %q", msg, "peer aborted")
+	}
+}
+
+func TestRstStreamEmptyMessage(t *testing.T) {
+	frame := EncodeRstStream(1, ErrCodeUnknown, "")
+	code, msg, err := DecodeRstStreamPayload(frame.Payload)
+	if err != nil {
+		t.Fatalf("DecodeRstStreamPayload failed: %v", err)
+	}
+	if code != ErrCodeUnknown {
+		t.Errorf("code = %d, want ErrCodeUnknown", code)
+	}
+	if msg != "" {
+		t.Errorf("msg = %q, want empty", msg)
+	}
+}
+
+func TestRstStreamOversizedMessageTruncated(t *testing.T) {
+	huge := strings.Repeat("x", MaxRstStreamMessage*2)
+	frame := EncodeRstStream(1, ErrCodeUnknown, huge)
+
+	if len(frame.Payload)-rstStreamHeaderSize != MaxRstStreamMessage {
+		t.Fatalf("payload message length = %d, want %d", len(frame.Payload)-rstStreamHeaderSize, MaxRstStreamMessage)
+	}
+
+	_, msg, err := DecodeRstStreamPayload(frame.Payload)
+	if err != nil {
+		t.Fatalf("DecodeRstStreamPayload failed: %v", err)
+	}
+	if len(msg) != MaxRstStreamMessage {
+		t.Errorf("decoded msg length = %d, want %d", len(msg), MaxRstStreamMessage)
+	}
+}
+
+// TestRstStreamOversizedMultiByteMessageStaysValidUTF8 ensures truncation
+// never splits a multi-byte rune in half, which would emit invalid UTF-8 on
+// the wire.
+func TestRstStreamOversizedMultiByteMessageStaysValidUTF8(t *testing.T) {
+	// "猫" is 3 bytes in UTF-8; repeating it means a naive byte-count
+	// truncation at MaxRstStreamMessage (4096, not a multiple of 3) would
+	// land mid-rune.
+	huge := strings.Repeat("猫", MaxRstStreamMessage)
+	frame := EncodeRstStream(1, ErrCodeUnknown, huge)
+
+	_, msg, err := DecodeRstStreamPayload(frame.Payload)
+	if err != nil {
+		t.Fatalf("DecodeRstStreamPayload failed: %v", err)
+	}
+	if !utf8.ValidString(msg) {
+		t.Errorf("truncated message is not valid UTF-8: %q", msg)
+	}
+	if len(msg) > MaxRstStreamMessage {
+		t.Errorf("truncated message length = %d, want <= %d", len(msg), MaxRstStreamMessage)
+	}
+}
+
+func TestRstStreamBadPayload(t *testing.T) {
+	_, _, err := DecodeRstStreamPayload([]byte{0x01})
+	if err == nil {
+		t.Fatal("expected error for payload shorter than 2 bytes")
+	}
+	if pe, ok := IsProtocolError(err); !ok || pe.Code != ErrCodeBadPayload {
+		t.Errorf("expected ErrCodeBadPayload, got %v", err)
+	}
+}
+
+// TestRstStreamAfterEndStream documents that RST_STREAM aborts a stream
+// outright: it is valid (and meaningful) even after that stream already sent
+// FlagEndStream, unlike FrameClose which expects a clean FIN handshake.
+func TestRstStreamAfterEndStream(t *testing.T) {
+	dataFrame := &Frame{
+		Version:  Version,
+		Type:     FrameData,
+		Flags:    FlagEndStream,
+		StreamID: 5,
+		Payload:  []byte("final chunk"),
+	}
+	if !dataFrame.IsEndStream() {
+		t.Fatal("expected data frame to carry FlagEndStream")
+	}
+
+	rst := EncodeRstStream(5, ErrCodeStreamClosed, "late abort after FIN")
+	if rst.StreamID != dataFrame.StreamID {
+		t.Fatalf("rst StreamID = %d, want %d", rst.StreamID, dataFrame.StreamID)
+	}
+	if rst.IsEndStream() {
+		t.Error("RST_STREAM should not itself carry FlagEndStream; it closes the stream directly")
+	}
+
+	code, _, err := DecodeRstStreamPayload(rst.Payload)
+	if err != nil {
+		t.Fatalf("DecodeRstStreamPayload failed: %v", err)
+	}
+	if code != ErrCodeStreamClosed {
+		t.Errorf("code = %d, want ErrCodeStreamClosed", code)
+	}
+}
@@ -0,0 +1,97 @@
+package v1
+
+import "encoding/binary"
+
+// SettingID identifies a single negotiable parameter carried in a SETTINGS
+// frame. Unknown IDs must be ignored by the receiver (forward-compat), the
+// same pattern HTTP/2 uses for its SETTINGS frame.
+type SettingID uint16
+
+const (
+	SettingMaxFrameSize         SettingID = 0x01
+	SettingMaxConcurrentStreams SettingID = 0x02
+	SettingInitialWindowSize    SettingID = 0x03
+	SettingHeartbeatInterval    SettingID = 0x04
+	SettingMaxPayloadSize       SettingID = 0x05
+)
+
+// settingEntrySize: SettingID(2) + Value(4) = 6 bytes per tuple.
+const settingEntrySize = 6
+
+// Settings holds the (SettingID, Value) tuples negotiated for a connection.
+// Values is keyed by SettingID so callers can look up only the settings they
+// understand; entries with an unrecognized SettingID are carried through
+// unharmed and simply never looked up.
+type Settings struct {
+	Values map[SettingID]uint32
+}
+
+// NewSettings returns an empty Settings ready to be populated.
+func NewSettings() *Settings {
+	return &Settings{Values: make(map[SettingID]uint32)}
+}
+
+// Set records a (SettingID, Value) pair to be sent.
+func (s *Settings) Set(id SettingID, value uint32) {
+	if s.Values == nil {
+		s.Values = make(map[SettingID]uint32)
+	}
+	s.Values[id] = value
+}
+
+// Get returns the negotiated value for id and whether it was present.
+func (s *Settings) Get(id SettingID) (uint32, bool) {
+	v, ok := s.Values[id]
+	return v, ok
+}
+
+// EncodeSettings builds a SETTINGS frame carrying s as a sequence of
+// (SettingID uint16, Value uint32) tuples, big-endian, 6 bytes per entry.
+func EncodeSettings(s *Settings) *Frame {
+	payload := make([]byte, 0, len(s.Values)*settingEntrySize)
+	for id, value := range s.Values {
+		var entry [settingEntrySize]byte
+		binary.BigEndian.PutUint16(entry[0:2], uint16(id))
+		binary.BigEndian.PutUint32(entry[2:6], value)
+		payload = append(payload, entry[:]...)
+	}
+
+	return &Frame{
+		Version:  Version,
+		Type:     FrameSettings,
+		Flags:    FlagNone,
+		StreamID: StreamIDControl,
+		Payload:  payload,
+	}
+}
+
+// AckSettingsFrame builds the empty SETTINGS frame with FlagAck set that a
+// receiver must echo back to acknowledge a peer's SETTINGS frame.
+func AckSettingsFrame() *Frame {
+	return &Frame{
+		Version:  Version,
+		Type:     FrameSettings,
+		Flags:    FlagAck,
+		StreamID: StreamIDControl,
+	}
+}
+
+// DecodeSettings parses a SETTINGS payload into a Settings. Payload length
+// must be a multiple of settingEntrySize; any leftover trailing bytes are
+// rejected as a malformed payload. Unknown SettingIDs are not an error - they
+// are kept in Values like any other entry and simply go unused by callers
+// that don't recognize them.
+func DecodeSettings(payload []byte) (*Settings, error) {
+	if len(payload)%settingEntrySize != 0 {
+		return nil, NewError(ErrCodeBadPayload, "settings payload not a multiple of entry size")
+	}
+
+	s := NewSettings()
+	for i := 0; i+settingEntrySize <= len(payload); i += settingEntrySize {
+		id := SettingID(binary.BigEndian.Uint16(payload[i : i+2]))
+		value := binary.BigEndian.Uint32(payload[i+2 : i+6])
+		s.Values[id] = value
+	}
+
+	return s, nil
+}
@@ -0,0 +1,68 @@
+package v1
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// SettingsNegotiator tracks a single outstanding local SETTINGS frame until
+// the peer's ACK arrives (HandleAck) or a deadline passes, mirroring the
+// wait/timeout pattern Pinger uses for heartbeats.
+type SettingsNegotiator struct {
+	mu    sync.Mutex
+	sent  bool
+	acked chan struct{}
+}
+
+// NewSettingsNegotiator returns a SettingsNegotiator ready to track one
+// SETTINGS round trip.
+func NewSettingsNegotiator() *SettingsNegotiator {
+	return &SettingsNegotiator{acked: make(chan struct{})}
+}
+
+// MarkSent records that a local SETTINGS frame was just sent and is now
+// awaiting the peer's ACK.
+func (n *SettingsNegotiator) MarkSent() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.sent = true
+}
+
+// HandleAck completes the outstanding wait when fed the peer's SETTINGS ACK.
+// Frames that aren't an acked SETTINGS frame, or that arrive with nothing
+// outstanding, are ignored.
+func (n *SettingsNegotiator) HandleAck(frame *Frame) {
+	if frame.Type != FrameSettings || !frame.IsAck() {
+		return
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if !n.sent {
+		return
+	}
+
+	select {
+	case <-n.acked:
+		// Already acked.
+	default:
+		close(n.acked)
+	}
+}
+
+// WaitForAck blocks until HandleAck completes the wait, ctx is done, or
+// timeout elapses.
+func (n *SettingsNegotiator) WaitForAck(ctx context.Context, timeout time.Duration) error {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case <-n.acked:
+		return nil
+	case <-timer.C:
+		return NewError(ErrCodeUnknown, "settings ack timed out")
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
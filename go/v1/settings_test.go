@@ -0,0 +1,132 @@
+package v1
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+)
+
+func TestSettingsRoundTrip(t *testing.T) {
+	settings := NewSettings()
+	settings.Set(SettingMaxFrameSize, 1<<20)
+	settings.Set(SettingMaxConcurrentStreams, 100)
+	settings.Set(SettingInitialWindowSize, 65535)
+	settings.Set(SettingHeartbeatInterval, 30)
+	settings.Set(SettingMaxPayloadSize, 4096)
+
+	frame := EncodeSettings(settings)
+	if frame.Type != FrameSettings {
+		t.Fatalf("Type = %d, want FrameSettings", frame.Type)
+	}
+	if frame.StreamID != StreamIDControl {
+		t.Fatalf("StreamID = %d, want StreamIDControl", frame.StreamID)
+	}
+
+	var buf bytes.Buffer
+	if err := Encode(&buf, frame); err != nil {
+		t.Fatalf("Encode failed: %v", err)
+	}
+
+	decoded, err := Decode(&buf)
+	if err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+
+	got, err := DecodeSettings(decoded.Payload)
+	if err != nil {
+		t.Fatalf("DecodeSettings failed: %v", err)
+	}
+
+	for id, want := range settings.Values {
+		v, ok := got.Get(id)
+		if !ok {
+			t.Errorf("setting %d missing after round trip", id)
+			continue
+		}
+		if v != want {
+			t.Errorf("setting %d = %d, want %d", id, v, want)
+		}
+	}
+}
+
+func TestSettingsUnknownIDIgnored(t *testing.T) {
+	unknownID := SettingID(0xBEEF)
+
+	payload := make([]byte, settingEntrySize)
+	payload[0], payload[1] = byte(unknownID>>8), byte(unknownID)
+	payload[5] = 0x01 // value = 1
+
+	got, err := DecodeSettings(payload)
+	if err != nil {
+		t.Fatalf("DecodeSettings failed on unknown ID: %v", err)
+	}
+
+	if _, ok := got.Get(SettingMaxFrameSize); ok {
+		t.Error("expected no known settings to be populated")
+	}
+	if v, ok := got.Get(unknownID); !ok || v != 1 {
+		t.Errorf("expected unknown setting to survive decode, got %d, %v", v, ok)
+	}
+}
+
+func TestSettingsBadPayloadLength(t *testing.T) {
+	_, err := DecodeSettings([]byte{0x00, 0x01, 0x02})
+	if err == nil {
+		t.Fatal("expected error for payload not a multiple of entry size")
+	}
+
+	pe, ok := IsProtocolError(err)
+	if !ok || pe.Code != ErrCodeBadPayload {
+		t.Errorf("expected ErrCodeBadPayload, got %v", err)
+	}
+}
+
+func TestSettingsAck(t *testing.T) {
+	ack := AckSettingsFrame()
+	if !ack.IsAck() {
+		t.Fatal("expected ACK frame to have FlagAck set")
+	}
+	if len(ack.Payload) != 0 {
+		t.Errorf("expected empty ACK payload, got %d bytes", len(ack.Payload))
+	}
+}
+
+func TestSettingsNegotiatorAck(t *testing.T) {
+	n := NewSettingsNegotiator()
+	n.MarkSent()
+
+	go n.HandleAck(AckSettingsFrame())
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	if err := n.WaitForAck(ctx, time.Second); err != nil {
+		t.Fatalf("WaitForAck failed: %v", err)
+	}
+}
+
+func TestSettingsNegotiatorIgnoresNonAckAndUnrelatedFrames(t *testing.T) {
+	n := NewSettingsNegotiator()
+	n.MarkSent()
+
+	n.HandleAck(EncodeSettings(NewSettings()))                             // SETTINGS without FlagAck
+	n.HandleAck(&Frame{Version: Version, Type: FrameData, Flags: FlagAck}) // ack, wrong type
+
+	if err := n.WaitForAck(context.Background(), 30*time.Millisecond); err == nil {
+		t.Fatal("expected WaitForAck to still be pending after non-matching frames")
+	}
+}
+
+// TestSettingsAckTimeout simulates a peer that never acknowledges a SETTINGS
+// frame: the waiter must give up after its deadline instead of blocking
+// forever.
+func TestSettingsAckTimeout(t *testing.T) {
+	n := NewSettingsNegotiator()
+	n.MarkSent()
+
+	err := n.WaitForAck(context.Background(), 50*time.Millisecond)
+	if err == nil {
+		t.Fatal("expected WaitForAck to time out when no ACK ever arrives")
+	}
+}